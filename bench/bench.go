@@ -0,0 +1,152 @@
+// Package bench implements a small worker-pool load-test mode: N workers
+// pull cloned requests off a channel and round-trip them concurrently,
+// and the results are aggregated into a latency/throughput report.
+package bench
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Result is the outcome of a single request.
+type Result struct {
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+// Report summarizes a completed load test.
+type Report struct {
+	Requests    int
+	Errors      int
+	StatusCodes map[int]int
+	P50         time.Duration
+	P90         time.Duration
+	P99         time.Duration
+	Total       time.Duration
+	Throughput  float64 // requests per second
+}
+
+// Run sends repeat clones of req through rt using parallel concurrent
+// workers, and returns the aggregated report.
+func Run(rt http.RoundTripper, req *http.Request, parallel, repeat int) (*Report, error) {
+	if parallel < 1 {
+		parallel = 1
+	}
+	if repeat < 1 {
+		repeat = 1
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("bench: %w", err)
+	}
+
+	jobs := make(chan *http.Request, repeat)
+	results := make(chan Result, repeat)
+
+	for w := 0; w < parallel; w++ {
+		go worker(rt, jobs, results)
+	}
+
+	start := time.Now()
+	go func() {
+		for i := 0; i < repeat; i++ {
+			jobs <- cloneRequest(req, body)
+		}
+		close(jobs)
+	}()
+
+	durations := make([]time.Duration, 0, repeat)
+	statusCodes := map[int]int{}
+	errs := 0
+	for i := 0; i < repeat; i++ {
+		r := <-results
+		if r.Err != nil {
+			errs++
+			continue
+		}
+		durations = append(durations, r.Duration)
+		statusCodes[r.StatusCode]++
+	}
+	total := time.Since(start)
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	report := &Report{
+		Requests:    repeat,
+		Errors:      errs,
+		StatusCodes: statusCodes,
+		P50:         percentile(durations, 50),
+		P90:         percentile(durations, 90),
+		P99:         percentile(durations, 99),
+		Total:       total,
+		Throughput:  float64(repeat) / total.Seconds(),
+	}
+	return report, nil
+}
+
+func worker(rt http.RoundTripper, jobs <-chan *http.Request, results chan<- Result) {
+	for req := range jobs {
+		start := time.Now()
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			results <- Result{Err: err, Duration: time.Since(start)}
+			continue
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		results <- Result{StatusCode: resp.StatusCode, Duration: time.Since(start)}
+	}
+}
+
+// readBody buffers req's body (if any) so it can be replayed across every
+// worker's clone of req.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
+
+func cloneRequest(req *http.Request, body []byte) *http.Request {
+	clone := req.Clone(req.Context())
+	if body != nil {
+		clone.Body = io.NopCloser(bytes.NewReader(body))
+		clone.ContentLength = int64(len(body))
+	}
+	return clone
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := (p * len(sorted)) / 100
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// Print writes a human-readable report to w.
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "Requests:    %d (%d errors)\n", r.Requests, r.Errors)
+	fmt.Fprintf(w, "Total time:  %s\n", r.Total)
+	fmt.Fprintf(w, "Throughput:  %.2f req/s\n", r.Throughput)
+	fmt.Fprintf(w, "Latency:     p50=%s p90=%s p99=%s\n", r.P50, r.P90, r.P99)
+	fmt.Fprintln(w, "Status codes:")
+	codes := make([]int, 0, len(r.StatusCodes))
+	for code := range r.StatusCodes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		fmt.Fprintf(w, "  %d: %d\n", code, r.StatusCodes[code])
+	}
+}
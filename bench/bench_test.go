@@ -0,0 +1,69 @@
+package bench
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	durations := []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		30 * time.Millisecond,
+		40 * time.Millisecond,
+		50 * time.Millisecond,
+	}
+
+	tests := []struct {
+		p    int
+		want time.Duration
+	}{
+		{p: 0, want: 10 * time.Millisecond},
+		{p: 50, want: 30 * time.Millisecond},
+		{p: 99, want: 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := percentile(durations, tt.p); got != tt.want {
+			t.Errorf("percentile(durations, %d) = %s, want %s", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestPercentile_Empty(t *testing.T) {
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile(nil, 50) = %s, want 0", got)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRun_AggregatesAllWorkerResults(t *testing.T) {
+	const repeat = 20
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: http.NoBody}, nil
+	})
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(rt, req, 4, repeat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Requests != repeat {
+		t.Errorf("Requests = %d, want %d", report.Requests, repeat)
+	}
+	if report.Errors != 0 {
+		t.Errorf("Errors = %d, want 0", report.Errors)
+	}
+	if report.StatusCodes[200] != repeat {
+		t.Errorf("StatusCodes[200] = %d, want %d", report.StatusCodes[200], repeat)
+	}
+}
@@ -0,0 +1,118 @@
+// Package collection parses Postman/Hoppscotch-style collection exports and
+// flattens them into an ordered list of requests that can be replayed.
+package collection
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// Header is a single HTTP header entry within a collection request.
+type Header struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Param is a single query parameter entry within a collection request.
+type Param struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Request describes one request in a collection, before folders are
+// flattened and variables are substituted.
+type Request struct {
+	URL     string   `json:"url"`
+	Path    string   `json:"path"`
+	Method  string   `json:"method"`
+	Headers []Header `json:"headers"`
+	Params  []Param  `json:"params"`
+	Body    string   `json:"body"`
+}
+
+// Folder is a named group of requests and nested folders.
+type Folder struct {
+	Name     string    `json:"name"`
+	Folders  []Folder  `json:"Folders"`
+	Requests []Request `json:"Requests"`
+}
+
+// Collection is the top-level document produced by Postman/Hoppscotch
+// exports that this tool understands.
+type Collection struct {
+	Name     string    `json:"name"`
+	Folders  []Folder  `json:"Folders"`
+	Requests []Request `json:"Requests"`
+}
+
+// Load reads and parses a collection JSON file.
+func Load(filename string) (*Collection, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("collection: read %s: %w", filename, err)
+	}
+	var c Collection
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("collection: parse %s: %w", filename, err)
+	}
+	return &c, nil
+}
+
+// LoadEnv reads a flat `{"var": "value"}` environment file, as passed via
+// --env.
+func LoadEnv(filename string) (map[string]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("collection: read env %s: %w", filename, err)
+	}
+	env := map[string]string{}
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("collection: parse env %s: %w", filename, err)
+	}
+	return env, nil
+}
+
+// Flatten walks a collection's folders depth-first and returns every
+// request in document order.
+func Flatten(c *Collection) []Request {
+	var out []Request
+	out = append(out, c.Requests...)
+	for _, f := range c.Folders {
+		out = append(out, flattenFolder(f)...)
+	}
+	return out
+}
+
+func flattenFolder(f Folder) []Request {
+	var out []Request
+	out = append(out, f.Requests...)
+	for _, nested := range f.Folders {
+		out = append(out, flattenFolder(nested)...)
+	}
+	return out
+}
+
+var varPattern = regexp.MustCompile(`\{\{\s*([\w.-]+)\s*\}\}`)
+
+// Substitute replaces every `{{var}}` occurrence in s with its value from
+// env. Unknown variables are left untouched.
+func Substitute(s string, env map[string]string) string {
+	return varPattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := varPattern.FindStringSubmatch(match)[1]
+		if v, ok := env[name]; ok {
+			return v
+		}
+		return match
+	})
+}
+
+// ResolveURL returns the request's effective URL: URL if set, otherwise
+// Path, with environment substitution applied.
+func (r Request) ResolveURL(env map[string]string) string {
+	if r.URL != "" {
+		return Substitute(r.URL, env)
+	}
+	return Substitute(r.Path, env)
+}
@@ -0,0 +1,74 @@
+package collection
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlatten_DepthFirstDocumentOrder(t *testing.T) {
+	c := &Collection{
+		Requests: []Request{{Path: "/top"}},
+		Folders: []Folder{
+			{
+				Name:     "outer",
+				Requests: []Request{{Path: "/outer-a"}, {Path: "/outer-b"}},
+				Folders: []Folder{
+					{
+						Name:     "inner",
+						Requests: []Request{{Path: "/inner-a"}},
+					},
+				},
+			},
+			{
+				Name:     "sibling",
+				Requests: []Request{{Path: "/sibling-a"}},
+			},
+		},
+	}
+
+	got := Flatten(c)
+	var paths []string
+	for _, r := range got {
+		paths = append(paths, r.Path)
+	}
+
+	want := []string{"/top", "/outer-a", "/outer-b", "/inner-a", "/sibling-a"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Flatten order = %v, want %v", paths, want)
+	}
+}
+
+func TestSubstitute_ReplacesKnownVarsAndLeavesUnknownUntouched(t *testing.T) {
+	env := map[string]string{"host": "example.com"}
+
+	got := Substitute("https://{{host}}/users/{{id}}", env)
+	want := "https://example.com/users/{{id}}"
+	if got != want {
+		t.Errorf("Substitute() = %q, want %q", got, want)
+	}
+}
+
+func TestRunner_BuildSubstitutesParamsAndHeaders(t *testing.T) {
+	r := &Runner{Env: map[string]string{"token": "secret", "name": "alice"}}
+
+	spec := Request{
+		Method: "GET",
+		URL:    "http://example.com/users",
+		Params: []Param{{Key: "name", Value: "{{name}}"}},
+		Headers: []Header{
+			{Key: "Authorization", Value: "Bearer {{token}}"},
+		},
+	}
+
+	req, err := r.build(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := req.URL.Query().Get("name"); got != "alice" {
+		t.Errorf("query param name = %q, want %q", got, "alice")
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization header = %q, want %q", got, "Bearer secret")
+	}
+}
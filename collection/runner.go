@@ -0,0 +1,107 @@
+package collection
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/wildberries-ru/go-curl-formatter/render"
+	"github.com/wildberries-ru/go-curl-formatter/transport"
+)
+
+// Runner executes a flattened list of collection requests sequentially,
+// printing the rendered body for each response.
+type Runner struct {
+	Client *http.Client
+	Env    map[string]string
+}
+
+// NewRunner builds a Runner sharing the same transport/redirect handling
+// used by the single-request curl path.
+func NewRunner(env map[string]string) *Runner {
+	return &Runner{
+		// Redirects are followed manually via transport.FollowRedirects,
+		// the same as visit in main.go, so the client itself must not
+		// follow them.
+		Client: transport.NewClient(false),
+		Env:    env,
+	}
+}
+
+// Run executes every request in order, returning an error if any
+// response has a status code >= 400 so callers can use it in CI.
+func (r *Runner) Run(reqs []Request) error {
+	var failed bool
+	for i, spec := range reqs {
+		req, err := r.build(spec)
+		if err != nil {
+			return fmt.Errorf("collection: request %d: %w", i, err)
+		}
+
+		resp, _, err := transport.FollowRedirects(r.Client, req, true, transport.DefaultMaxRedirects, traceHop(i))
+		if err != nil {
+			return fmt.Errorf("collection: request %d (%s %s): %w", i, spec.Method, req.URL, err)
+		}
+
+		if err := r.print(resp); err != nil {
+			resp.Body.Close()
+			return fmt.Errorf("collection: request %d: %w", i, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			failed = true
+		}
+	}
+	if failed {
+		return fmt.Errorf("collection: one or more requests returned a status >= 400")
+	}
+	return nil
+}
+
+// traceHop returns a transport.HopFunc that traces request i's redirect
+// chain to stderr, matching the hop trace the single-request curl path
+// prints.
+func traceHop(i int) transport.HopFunc {
+	return func(hop int, req *http.Request, resp *http.Response, timing *transport.Timing) {
+		fmt.Fprintf(os.Stderr, "* request %d [%d] %s %s -> %d (%s)\n", i, hop, req.Method, req.URL, resp.StatusCode, timing.TimeToFirstByte)
+	}
+}
+
+func (r *Runner) build(spec Request) (*http.Request, error) {
+	method := spec.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if spec.Body != "" {
+		body = strings.NewReader(Substitute(spec.Body, r.Env))
+	}
+
+	req, err := http.NewRequest(method, spec.ResolveURL(r.Env), body)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	for _, p := range spec.Params {
+		q.Set(Substitute(p.Key, r.Env), Substitute(p.Value, r.Env))
+	}
+	req.URL.RawQuery = q.Encode()
+
+	for _, h := range spec.Headers {
+		req.Header.Add(Substitute(h.Key, r.Env), Substitute(h.Value, r.Env))
+	}
+	return req, nil
+}
+
+// print renders resp's body the same way the single-request curl path
+// does: decoding Content-Encoding and dispatching on Content-Type rather
+// than assuming uncompressed JSON.
+func (r *Runner) print(resp *http.Response) error {
+	opts := render.Options{Color: render.IsTerminal(os.Stdout)}
+	return render.Response(resp, os.Stdout, opts)
+}
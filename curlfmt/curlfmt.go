@@ -0,0 +1,78 @@
+// Package curlfmt renders an *http.Request back into the equivalent curl
+// command line, so a request built by this tool can be reproduced outside
+// of it.
+package curlfmt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type contextKey int
+
+const bodyFileKey contextKey = 0
+
+// WithBodyFile tags req's context with the filename its body was read
+// from (the "@filename" form of --data), so FormatCurl can reproduce it
+// as `--data-binary @filename` instead of inlining the file's contents.
+func WithBodyFile(ctx context.Context, filename string) context.Context {
+	return context.WithValue(ctx, bodyFileKey, filename)
+}
+
+// FormatCurl renders req as the curl command line that would reproduce
+// it: method, URL, headers, host override and body all round-trip.
+func FormatCurl(req *http.Request) string {
+	var body string
+	if filename, ok := req.Context().Value(bodyFileKey).(string); ok && filename != "" {
+		body = " --data-binary " + quote("@"+filename)
+	} else if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err == nil {
+			data, err := io.ReadAll(rc)
+			rc.Close()
+			if err == nil && len(data) > 0 {
+				body = " -d " + quote(string(data))
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("curl")
+
+	// curl sends a request carrying -d/--data-binary as POST unless -X
+	// says otherwise, so a GET with a body needs an explicit -X GET.
+	if req.Method != "" && (req.Method != http.MethodGet || body != "") {
+		fmt.Fprintf(&b, " -X %s", req.Method)
+	}
+
+	fmt.Fprintf(&b, " %s", quote(req.URL.String()))
+
+	if req.Host != "" && req.Host != req.URL.Host {
+		fmt.Fprintf(&b, " -H %s", quote("Host: "+req.Host))
+	}
+
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range req.Header[k] {
+			fmt.Fprintf(&b, " -H %s", quote(k+": "+v))
+		}
+	}
+
+	b.WriteString(body)
+
+	return b.String()
+}
+
+// quote single-quotes s the way a shell would need it, escaping any
+// embedded single quotes.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
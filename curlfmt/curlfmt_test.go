@@ -0,0 +1,60 @@
+package curlfmt
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFormatCurl_RoundTripsMethodHeadersAndBody(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "http://example.com/path?q=1", strings.NewReader("it's a body"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("X-Token", "a b")
+	req.Header.Add("Accept", "application/json")
+
+	out := FormatCurl(req)
+
+	for _, want := range []string{
+		"-X POST",
+		"'http://example.com/path?q=1'",
+		`-H 'Accept: application/json'`,
+		`-H 'X-Token: a b'`,
+		`-d 'it'\''s a body'`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("FormatCurl output = %q, want it to contain %q", out, want)
+		}
+	}
+}
+
+func TestFormatCurl_BodyFileUsesDataBinary(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "http://example.com/upload", strings.NewReader("ignored"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = req.WithContext(WithBodyFile(req.Context(), "payload.bin"))
+
+	out := FormatCurl(req)
+
+	if !strings.Contains(out, "--data-binary '@payload.bin'") {
+		t.Errorf("FormatCurl output = %q, want --data-binary '@payload.bin'", out)
+	}
+	if strings.Contains(out, "ignored") {
+		t.Errorf("FormatCurl output = %q, should not inline the file's contents", out)
+	}
+}
+
+func TestFormatCurl_HostOverride(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://1.2.3.4/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Host = "example.com"
+
+	out := FormatCurl(req)
+	if !strings.Contains(out, "-H 'Host: example.com'") {
+		t.Errorf("FormatCurl output = %q, want a Host header override", out)
+	}
+}
@@ -1,22 +1,23 @@
 package main
 
 import (
-	"context"
-	"encoding/json"
+	"bytes"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"log"
-	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
-	"time"
+	"text/template"
 
-	"github.com/fatih/color"
-	jsonC "github.com/nwidger/jsoncolor"
 	"github.com/pborman/getopt"
+
+	"github.com/wildberries-ru/go-curl-formatter/bench"
+	"github.com/wildberries-ru/go-curl-formatter/collection"
+	"github.com/wildberries-ru/go-curl-formatter/curlfmt"
+	"github.com/wildberries-ru/go-curl-formatter/render"
+	"github.com/wildberries-ru/go-curl-formatter/transport"
 )
 
 var (
@@ -27,6 +28,13 @@ var (
 	onlyHeader      *bool
 	httpHeaders     []string
 	help            *bool
+	printCurl       *bool
+	maxRedirs       *int
+	outputFile      *string
+	showTiming      *bool
+	writeOut        *string
+	parallel        *int
+	repeat          *int
 )
 
 func main() {
@@ -37,11 +45,23 @@ func main() {
 		}
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "run" {
+		runCollection(os.Args[2:])
+		return
+	}
+
 	httpMethod = getopt.StringLong("request", 'X', "GET", "HTTP method to use")
 	help = getopt.BoolLong("help", 'h', "This help text")
 	postBody = getopt.StringLong("data", 'd', "", "HTTP POST data")
 	followRedirects = getopt.BoolLong("location", 'L', "Follow redirects")
 	onlyHeader = getopt.BoolLong("head", 'I', "Show document info only")
+	printCurl = getopt.BoolLong("curl", 'C', "Print the equivalent curl command instead of executing the request")
+	maxRedirs = getopt.IntLong("max-redirs", 0, 50, "Maximum number of redirects to follow when -L is set")
+	outputFile = getopt.StringLong("output", 'o', "", "Write the response body to file instead of stdout")
+	showTiming = getopt.BoolLong("timing", 0, "Print a DNS/connect/TLS/TTFB/total timing breakdown")
+	writeOut = getopt.StringLong("write-out", 0, "", "Go template for a custom summary line, e.g. '{{.TimeTotal}} {{.HTTPCode}}'")
+	parallel = getopt.IntLong("parallel", 0, 1, "Number of concurrent workers for load-test mode")
+	repeat = getopt.IntLong("repeat", 0, 1, "Number of requests to send in load-test mode")
 	_ = getopt.ListVarLong(&httpHeaders, "header", 'H', "set HTTP header; repeatable: -H 'Accept: ...' -H 'Range: ...'")
 	getopt.Parse()
 
@@ -64,7 +84,98 @@ func main() {
 	if *onlyHeader {
 		httpMethod = &head
 	}
-	visit(parseURL(args[0]))
+
+	url := parseURL(args[0])
+	if *printCurl {
+		fmt.Println(curlfmt.FormatCurl(newRequest(httpMethod, postBody, url)))
+		return
+	}
+
+	if *parallel > 1 || *repeat > 1 {
+		runLoadTest(url)
+		return
+	}
+
+	_, timing, err := visit(url)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	if *showTiming {
+		printTiming(timing)
+	}
+	if *writeOut != "" {
+		if err := printWriteOut(*writeOut, timing); err != nil {
+			log.Fatalf("write-out: %v", err)
+		}
+	}
+}
+
+// printTiming prints the DNS/connect/TLS/TTFB/total breakdown for timing,
+// similar to `curl -w` with a built-in format.
+func printTiming(timing *transport.Timing) {
+	fmt.Fprintf(os.Stderr, "DNS Lookup:    %s\n", timing.DNSLookup)
+	fmt.Fprintf(os.Stderr, "TCP Connect:   %s\n", timing.TCPConnect)
+	fmt.Fprintf(os.Stderr, "TLS Handshake: %s\n", timing.TLSHandshake)
+	fmt.Fprintf(os.Stderr, "TTFB:          %s\n", timing.TimeToFirstByte)
+	fmt.Fprintf(os.Stderr, "Total:         %s\n", timing.TimeTotal)
+}
+
+// printWriteOut renders tmplText as a Go template over timing, matching
+// curl's --write-out.
+func printWriteOut(tmplText string, timing *transport.Timing) error {
+	tmpl, err := template.New("write-out").Parse(tmplText)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(os.Stdout, timing); err != nil {
+		return err
+	}
+	fmt.Println()
+	return nil
+}
+
+// runCollection implements the `run` subcommand: it loads a Postman/
+// Hoppscotch-style collection, flattens it into an ordered request list,
+// and executes every request through collection.Runner.
+func runCollection(args []string) {
+	set := getopt.New()
+	envFile := set.StringLong("env", 0, "", "path to an environment JSON file for {{var}} substitution")
+	runHelp := set.BoolLong("help", 'h', "This help text")
+	if err := set.Getopt(args, nil); err != nil {
+		log.Fatalf("run: %v", err)
+	}
+
+	if *runHelp {
+		set.PrintUsage(os.Stdout)
+		os.Exit(0)
+	}
+
+	rest := set.Args()
+	if len(rest) != 1 {
+		log.Fatal("run: expected exactly one collection file argument")
+	}
+
+	var env map[string]string
+	if *envFile != "" {
+		loaded, err := collection.LoadEnv(*envFile)
+		if err != nil {
+			log.Fatalf("run: %v", err)
+		}
+		env = loaded
+	}
+
+	col, err := collection.Load(rest[0])
+	if err != nil {
+		log.Fatalf("run: %v", err)
+	}
+
+	reqs := collection.Flatten(col)
+	runner := collection.NewRunner(env)
+	if err := runner.Run(reqs); err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
 }
 
 func parseURL(uri string) (urlResponse *url.URL) {
@@ -91,61 +202,77 @@ func headerKeyValue(h string) (string, string) {
 	return strings.TrimRight(h[:i], " "), strings.TrimLeft(h[i:], " :")
 }
 
-func dialContext(network string) func(ctx context.Context, network, addr string) (net.Conn, error) {
-	return func(ctx context.Context, _, addr string) (net.Conn, error) {
-		return (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext(ctx, network, addr)
+// runLoadTest implements --parallel/--repeat: it reuses one Transport
+// (sized for the requested concurrency) across a worker pool and prints
+// an aggregated latency/throughput report. The single-request path above
+// is unaffected when neither flag is set above 1.
+func runLoadTest(url *url.URL) {
+	req := newRequest(httpMethod, postBody, url)
+
+	tr := transport.New()
+	tr.MaxIdleConnsPerHost = *parallel
+
+	report, err := bench.Run(tr, req, *parallel, *repeat)
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
+	report.Print(os.Stdout)
 }
 
-// visit visits a url and times the interaction.
-// If the response is a 30x, visit follows the redirect.
-func visit(url *url.URL) {
+// visit visits a url and times the interaction, returning the final
+// response and its timing breakdown. If the response is a 30x and -L was
+// given, visit follows the redirect itself via transport.FollowRedirects,
+// tracing each hop; otherwise it returns the 30x response as-is.
+func visit(url *url.URL) (*http.Response, *transport.Timing, error) {
 
 	req := newRequest(httpMethod, postBody, url)
 
-	tr := &http.Transport{
-		Proxy:                 http.ProxyFromEnvironment,
-		MaxIdleConns:          100,
-		IdleConnTimeout:       90 * time.Second,
-		TLSHandshakeTimeout:   10 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
-	}
-	tr.DialContext = dialContext("tcp4")
-
-	client := &http.Client{
-		Transport: tr,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			// always refuse to follow redirects, visit does that
-			// manually if required.
-			return http.ErrUseLastResponse
-		},
-	}
+	// visit does redirect-following manually if required, so the
+	// client itself never follows redirects.
+	client := transport.NewClient(false)
 
-	resp, err := client.Do(req)
+	resp, timing, err := transport.FollowRedirects(client, req, *followRedirects, *maxRedirs, traceHop)
 	if err != nil {
-		log.Fatalf("failed to read response: %v", err)
+		return nil, nil, err
 	}
 
-	bodyMsg := readResponseBody(req, resp)
-	err = resp.Body.Close()
-	if err != nil {
-		panic(err)
+	if err := emitResponse(resp.Request, resp); err != nil {
+		resp.Body.Close()
+		return nil, nil, err
 	}
-	fmt.Println(string(bodyMsg))
+	if err := resp.Body.Close(); err != nil {
+		return nil, nil, err
+	}
+	return resp, timing, nil
 }
 
-func isRedirect(resp *http.Response) bool {
-	return resp.StatusCode > 299 && resp.StatusCode < 400
+// traceHop prints a `* [hop] METHOD url -> status (ttfb)` line for each
+// redirect hop, similar to what `curl -v -L` produces.
+func traceHop(hop int, req *http.Request, resp *http.Response, timing *transport.Timing) {
+	fmt.Fprintf(os.Stderr, "* [%d] %s %s -> %d (%s)\n", hop, req.Method, req.URL, resp.StatusCode, timing.TimeToFirstByte)
 }
 
 func newRequest(method, body *string, url *url.URL) *http.Request {
-	req, err := http.NewRequest(*method, url.String(), createBody(*body))
+	data := readBody(*body)
+
+	req, err := http.NewRequest(*method, url.String(), bytes.NewReader(data))
 	if err != nil {
 		log.Fatalf("unable to create request: %v", err)
 	}
+	// Buffer the body up front and back it with GetBody rather than
+	// handing http.NewRequest a raw *os.File: the stdlib only populates
+	// GetBody for bytes.Buffer/bytes.Reader/strings.Reader, so without
+	// this a redirect hop that needs to replay a file-sourced body
+	// (307/308, see transport.NextRedirectRequest) would silently send
+	// it empty.
+	req.ContentLength = int64(len(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	if strings.HasPrefix(*body, "@") {
+		req = req.WithContext(curlfmt.WithBodyFile(req.Context(), (*body)[1:]))
+	}
 
 	for _, h := range httpHeaders {
 		k, v := headerKeyValue(h)
@@ -158,61 +285,38 @@ func newRequest(method, body *string, url *url.URL) *http.Request {
 	return req
 }
 
-func createBody(body string) io.Reader {
+// readBody resolves the --data value into the literal body bytes,
+// reading the referenced file up front when body uses the "@filename"
+// form.
+func readBody(body string) []byte {
 	if strings.HasPrefix(body, "@") {
 		filename := body[1:]
-		f, err := os.Open(filename)
+		data, err := os.ReadFile(filename)
 		if err != nil {
 			log.Fatalf("failed to open data file %s: %v", filename, err)
 		}
-		return f
+		return data
 	}
-	return strings.NewReader(body)
+	return []byte(body)
 }
 
-// readResponseBody ...
-func readResponseBody(req *http.Request, resp *http.Response) (response []byte) {
-	if isRedirect(resp) || req.Method == http.MethodHead {
-		return
-	}
-	f := jsonC.NewFormatter()
-	// set custom colors
-	f.StringColor = color.New(color.FgCyan)
-	f.TrueColor = color.New(color.FgCyan)
-	f.FalseColor = color.New(color.FgCyan)
-	f.NumberColor = color.New(color.FgCyan)
-	f.FieldColor = color.New(color.FgBlue)
-	f.FieldQuoteColor = color.New(color.FgBlue)
-	f.NullColor = color.New(color.FgCyan)
-
-	var jsonMaps []map[string]interface{}
-	var jsonMap map[string]interface{}
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatal(err)
+// emitResponse renders resp's body to stdout (or --output file, when set),
+// decoding compression and dispatching on Content-Type. HEAD responses and
+// intermediate redirect hops have no body worth rendering.
+func emitResponse(req *http.Request, resp *http.Response) error {
+	if transport.IsRedirect(resp) || req.Method == http.MethodHead {
+		return nil
 	}
 
-	if string(bodyBytes)[0] == '[' {
-		err = json.Unmarshal(bodyBytes, &jsonMaps)
-		if err != nil {
-			log.Fatal(err)
-		}
-		response, err = jsonC.MarshalIndentWithFormatter(jsonMaps, "", "  ", f)
+	if *outputFile != "" {
+		f, err := os.Create(*outputFile)
 		if err != nil {
-			log.Fatal(err)
+			return fmt.Errorf("failed to create output file %s: %w", *outputFile, err)
 		}
-
-	} else {
-		err = json.Unmarshal(bodyBytes, &jsonMap)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		response, err = jsonC.MarshalIndentWithFormatter(jsonMap, "", "  ", f)
-		if err != nil {
-			log.Fatal(err)
-		}
-
+		defer f.Close()
+		return render.Response(resp, f, render.Options{Color: false})
 	}
-	return
+
+	opts := render.Options{Color: render.IsTerminal(os.Stdout)}
+	return render.Response(resp, os.Stdout, opts)
 }
@@ -0,0 +1,28 @@
+// Package render holds the colorized output formatting shared by the curl
+// and collection-run code paths.
+package render
+
+import (
+	"github.com/fatih/color"
+	jsonC "github.com/nwidger/jsoncolor"
+)
+
+// Formatter returns a jsoncolor.Formatter configured with this tool's
+// color scheme.
+func Formatter() *jsonC.Formatter {
+	f := jsonC.NewFormatter()
+	f.StringColor = color.New(color.FgCyan)
+	f.TrueColor = color.New(color.FgCyan)
+	f.FalseColor = color.New(color.FgCyan)
+	f.NumberColor = color.New(color.FgCyan)
+	f.FieldColor = color.New(color.FgBlue)
+	f.FieldQuoteColor = color.New(color.FgBlue)
+	f.NullColor = color.New(color.FgCyan)
+	return f
+}
+
+// JSON marshals v using Formatter, matching the indentation used
+// elsewhere in this tool.
+func JSON(v interface{}) ([]byte, error) {
+	return jsonC.MarshalIndentWithFormatter(v, "", "  ", Formatter())
+}
@@ -0,0 +1,272 @@
+package render
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/fatih/color"
+)
+
+// Options controls how Response renders a body.
+type Options struct {
+	// Color enables ANSI colorization of structured output. Callers
+	// should set this to false when the destination isn't a terminal,
+	// matching curl's own behavior.
+	Color bool
+}
+
+// IsTerminal reports whether f is attached to a terminal, so callers can
+// decide whether colorized output is appropriate.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Response streams resp's body to w, transparently decoding
+// Content-Encoding and rendering the decoded bytes according to
+// Content-Type: colorized JSON/NDJSON, indented XML, tag-colorized HTML
+// (HTML is not re-indented), or a raw/hexdump fallback for everything
+// else. Apart from JSON, where each top-level value is decoded in full
+// before it is printed, it does not buffer the whole body in memory.
+func Response(resp *http.Response, w io.Writer, opts Options) error {
+	body, err := decodeContentEncoding(resp)
+	if err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
+	defer body.Close()
+
+	mediaType := baseMediaType(resp.Header.Get("Content-Type"))
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return renderJSONStream(body, w, opts)
+	case mediaType == "application/x-ndjson" || mediaType == "application/jsonlines":
+		return renderJSONStream(body, w, opts)
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return renderXML(body, w, opts)
+	case mediaType == "text/html":
+		return renderHTML(body, w, opts)
+	case strings.HasPrefix(mediaType, "text/"):
+		_, err := io.Copy(w, body)
+		return err
+	default:
+		return renderHexdump(body, w)
+	}
+}
+
+func decodeContentEncoding(resp *http.Response) (io.ReadCloser, error) {
+	switch strings.ToLower(resp.Header.Get("Content-Encoding")) {
+	case "gzip":
+		return gzipReadCloser(resp.Body)
+	case "deflate":
+		return deflateReadCloser(resp.Body)
+	case "br":
+		return io.NopCloser(brotli.NewReader(resp.Body)), nil
+	default:
+		return resp.Body, nil
+	}
+}
+
+func gzipReadCloser(r io.ReadCloser) (io.ReadCloser, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("gzip: %w", err)
+	}
+	return gr, nil
+}
+
+// deflateReadCloser handles both the zlib-wrapped and raw deflate streams
+// that servers send under the "deflate" Content-Encoding.
+func deflateReadCloser(r io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	peek, err := br.Peek(2)
+	if err == nil && len(peek) == 2 && isZlibHeader(peek) {
+		zr, err := zlib.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("deflate (zlib): %w", err)
+		}
+		return zr, nil
+	}
+	return flate.NewReader(br), nil
+}
+
+func isZlibHeader(b []byte) bool {
+	// RFC 1950: CMF/FLG header, CMF&0xf==8 (deflate) and the 16-bit
+	// value must be a multiple of 31.
+	return b[0]&0x0f == 8 && (uint16(b[0])<<8+uint16(b[1]))%31 == 0
+}
+
+func baseMediaType(contentType string) string {
+	if i := strings.Index(contentType, ";"); i != -1 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// renderJSONStream decodes one top-level JSON value at a time, so
+// newline-delimited or concatenated JSON is rendered value by value. Each
+// single value, however large (one big array or object included), is held
+// in memory in full while it is decoded and formatted.
+func renderJSONStream(body io.Reader, w io.Writer, opts Options) error {
+	dec := json.NewDecoder(body)
+	for {
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("render: decode json: %w", err)
+		}
+
+		if opts.Color {
+			out, err := JSON(v)
+			if err != nil {
+				return fmt.Errorf("render: format json: %w", err)
+			}
+			if _, err := w.Write(out); err != nil {
+				return err
+			}
+		} else {
+			out, err := json.MarshalIndent(v, "", "  ")
+			if err != nil {
+				return fmt.Errorf("render: format json: %w", err)
+			}
+			if _, err := w.Write(out); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// renderXML re-indents the element structure and, when colorized,
+// highlights tag names so the structure is easy to scan. Only element
+// names and text content are printed: attributes, namespace prefixes,
+// comments, processing instructions and directives are dropped.
+func renderXML(body io.Reader, w io.Writer, opts Options) error {
+	dec := xml.NewDecoder(body)
+	tagColor := color.New(color.FgBlue)
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("render: decode xml: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			fmt.Fprint(w, strings.Repeat("  ", depth))
+			writeTag(w, tagColor, opts.Color, "<"+t.Name.Local+">")
+			fmt.Fprintln(w)
+			depth++
+		case xml.EndElement:
+			depth--
+			fmt.Fprint(w, strings.Repeat("  ", depth))
+			writeTag(w, tagColor, opts.Color, "</"+t.Name.Local+">")
+			fmt.Fprintln(w)
+		case xml.CharData:
+			if text := strings.TrimSpace(string(t)); text != "" {
+				fmt.Fprintln(w, strings.Repeat("  ", depth)+text)
+			}
+		}
+	}
+}
+
+// renderHTML does a lightweight tag-aware pass: it doesn't attempt to
+// build a DOM, just colorizes each <...> tag as it streams through.
+func renderHTML(body io.Reader, w io.Writer, opts Options) error {
+	tagColor := color.New(color.FgBlue)
+	if !opts.Color {
+		_, err := io.Copy(w, body)
+		return err
+	}
+
+	br := bufio.NewReader(body)
+	inTag := false
+	var tag strings.Builder
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		switch {
+		case b == '<':
+			inTag = true
+			tag.Reset()
+			tag.WriteByte(b)
+		case inTag && b == '>':
+			tag.WriteByte(b)
+			tagColor.Fprint(w, tag.String())
+			inTag = false
+		case inTag:
+			tag.WriteByte(b)
+		default:
+			w.Write([]byte{b})
+		}
+	}
+}
+
+func writeTag(w io.Writer, c *color.Color, enabled bool, s string) {
+	if enabled {
+		c.Fprint(w, s)
+		return
+	}
+	fmt.Fprint(w, s)
+}
+
+// renderHexdump prints body in the classic 16-bytes-per-line hexdump
+// format used as a fallback for binary content types.
+func renderHexdump(body io.Reader, w io.Writer) error {
+	buf := make([]byte, 16)
+	offset := 0
+	for {
+		n, err := io.ReadFull(body, buf)
+		if n > 0 {
+			fmt.Fprintf(w, "%08x  ", offset)
+			for i := 0; i < 16; i++ {
+				if i < n {
+					fmt.Fprintf(w, "%02x ", buf[i])
+				} else {
+					fmt.Fprint(w, "   ")
+				}
+				if i == 7 {
+					fmt.Fprint(w, " ")
+				}
+			}
+			fmt.Fprint(w, " |")
+			for i := 0; i < n; i++ {
+				if buf[i] >= 0x20 && buf[i] < 0x7f {
+					fmt.Fprintf(w, "%c", buf[i])
+				} else {
+					fmt.Fprint(w, ".")
+				}
+			}
+			fmt.Fprintln(w, "|")
+			offset += n
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("render: hexdump: %w", err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package render
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newResponse(contentType, contentEncoding string, body []byte) *http.Response {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	if contentEncoding != "" {
+		header.Set("Content-Encoding", contentEncoding)
+	}
+	return &http.Response{
+		Header: header,
+		Body:   io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+func TestResponse_JSONStream(t *testing.T) {
+	resp := newResponse("application/json", "", []byte(`{"a":1}`))
+
+	var out bytes.Buffer
+	if err := Response(resp, &out, Options{Color: false}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"a": 1`) {
+		t.Errorf("output = %q, want it to contain formatted field %q", out.String(), `"a": 1`)
+	}
+}
+
+func TestResponse_NDJSONStreamsEachValue(t *testing.T) {
+	body := []byte("{\"a\":1}\n{\"a\":2}\n")
+	resp := newResponse("application/x-ndjson", "", body)
+
+	var out bytes.Buffer
+	if err := Response(resp, &out, Options{Color: false}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(out.String(), `"a"`) != 2 {
+		t.Errorf("output = %q, want two decoded objects", out.String())
+	}
+}
+
+func TestResponse_DecodesGzip(t *testing.T) {
+	var compressed bytes.Buffer
+	gw := gzip.NewWriter(&compressed)
+	gw.Write([]byte("hello world"))
+	gw.Close()
+
+	resp := newResponse("text/plain", "gzip", compressed.Bytes())
+
+	var out bytes.Buffer
+	if err := Response(resp, &out, Options{Color: false}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "hello world" {
+		t.Errorf("decoded body = %q, want %q", out.String(), "hello world")
+	}
+}
+
+func TestResponse_HexdumpFallbackForBinary(t *testing.T) {
+	resp := newResponse("application/octet-stream", "", []byte{0x00, 0x01, 0x02, 'A', 'B'})
+
+	var out bytes.Buffer
+	if err := Response(resp, &out, Options{Color: false}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "00000000") {
+		t.Errorf("output = %q, want a hexdump starting with an offset", out.String())
+	}
+	if !strings.Contains(out.String(), "AB") {
+		t.Errorf("output = %q, want the ASCII gutter to show printable bytes", out.String())
+	}
+}
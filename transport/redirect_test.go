@@ -0,0 +1,154 @@
+package transport
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNextRedirectRequest_RewritesMethodAndDropsBody(t *testing.T) {
+	prev, err := http.NewRequest(http.MethodPost, "http://example.com/a", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := &http.Response{StatusCode: http.StatusFound, Header: http.Header{"Location": {"/b"}}}
+
+	next, err := NextRedirectRequest(prev, resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Method != http.MethodGet {
+		t.Errorf("302 redirect: method = %q, want GET", next.Method)
+	}
+	if next.Body != nil {
+		t.Errorf("302 redirect: body = %v, want nil (dropped)", next.Body)
+	}
+}
+
+func TestNextRedirectRequest_PreservesMethodAndBodyOn307(t *testing.T) {
+	prev, err := http.NewRequest(http.MethodPost, "http://example.com/a", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := &http.Response{StatusCode: http.StatusTemporaryRedirect, Header: http.Header{"Location": {"/b"}}}
+
+	next, err := NextRedirectRequest(prev, resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Method != http.MethodPost {
+		t.Errorf("307 redirect: method = %q, want POST", next.Method)
+	}
+	if next.Body == nil {
+		t.Fatal("307 redirect: body was dropped, want it preserved")
+	}
+	got, err := io.ReadAll(next.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "hello" {
+		t.Errorf("307 redirect: body = %q, want %q", got, "hello")
+	}
+}
+
+func TestNextRedirectRequest_DropsAuthorizationOnHostChange(t *testing.T) {
+	prev, err := http.NewRequest(http.MethodGet, "http://a.example.com/x", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev.Header.Set("Authorization", "Bearer secret")
+
+	resp := &http.Response{StatusCode: http.StatusFound, Header: http.Header{"Location": {"http://b.example.com/y"}}}
+	next, err := NextRedirectRequest(prev, resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Header.Get("Authorization") != "" {
+		t.Errorf("Authorization leaked across host change: %q", next.Header.Get("Authorization"))
+	}
+}
+
+func TestNextRedirectRequest_SetsGetBodyOn307(t *testing.T) {
+	prev, err := http.NewRequest(http.MethodPost, "http://example.com/a", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := &http.Response{StatusCode: http.StatusTemporaryRedirect, Header: http.Header{"Location": {"/b"}}}
+
+	next, err := NextRedirectRequest(prev, resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.GetBody == nil {
+		t.Fatal("307 redirect: next.GetBody is nil, want a replayable body for any further hop")
+	}
+	rc, err := next.GetBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "hello" {
+		t.Errorf("next.GetBody() = %q, want %q", got, "hello")
+	}
+}
+
+func TestFollowRedirects_PreservesBodyAcrossTwoConsecutive307Hops(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/step0":
+			http.Redirect(w, r, "/step1", http.StatusTemporaryRedirect)
+		case "/step1":
+			http.Redirect(w, r, "/step2", http.StatusTemporaryRedirect)
+		case "/step2":
+			body, _ := io.ReadAll(r.Body)
+			w.Write(body)
+		}
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/step0", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, _, err := FollowRedirects(NewClient(false), req, true, DefaultMaxRedirects, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The second 307 hop (step1 -> step2) is the one that needs to
+	// replay a body off a request that was itself built by
+	// NextRedirectRequest, rather than the original request.
+	if string(got) != "payload" {
+		t.Errorf("body arriving at the final hop = %q, want %q", got, "payload")
+	}
+}
+
+func TestFollowRedirects_DetectsLoop(t *testing.T) {
+	var addr string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, addr, http.StatusFound)
+	}))
+	defer srv.Close()
+	addr = srv.URL
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = FollowRedirects(NewClient(false), req, true, DefaultMaxRedirects, nil)
+	if err == nil {
+		t.Fatal("expected a redirect loop error, got nil")
+	}
+}
@@ -0,0 +1,60 @@
+package transport
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+)
+
+// Timing is a per-phase breakdown of a single HTTP round trip, similar to
+// what `curl -w` reports. TimeTotal is left zero by Do, since it returns
+// before the response body is read; callers that care about wall-clock
+// total (including body transfer) should set it themselves once they're
+// done with the response.
+type Timing struct {
+	DNSLookup       time.Duration
+	TCPConnect      time.Duration
+	TLSHandshake    time.Duration
+	TimeToFirstByte time.Duration
+	TimeTotal       time.Duration
+	HTTPCode        int
+}
+
+// Do performs req on client while recording a Timing via
+// net/http/httptrace, returning the response alongside it.
+func Do(client *http.Client, req *http.Request) (*http.Response, *Timing, error) {
+	var dnsStart, dnsDone, connectStart, connectDone, tlsStart, tlsDone, firstByte time.Time
+	start := time.Now()
+
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { dnsDone = time.Now() },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { connectDone = time.Now() },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { tlsDone = time.Now() },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	timing := &Timing{HTTPCode: resp.StatusCode}
+	if !dnsStart.IsZero() && !dnsDone.IsZero() {
+		timing.DNSLookup = dnsDone.Sub(dnsStart)
+	}
+	if !connectStart.IsZero() && !connectDone.IsZero() {
+		timing.TCPConnect = connectDone.Sub(connectStart)
+	}
+	if !tlsStart.IsZero() && !tlsDone.IsZero() {
+		timing.TLSHandshake = tlsDone.Sub(tlsStart)
+	}
+	if !firstByte.IsZero() {
+		timing.TimeToFirstByte = firstByte.Sub(start)
+	}
+	return resp, timing, nil
+}
@@ -0,0 +1,57 @@
+package transport
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestDo_PopulatesTimingFields(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, timing, err := Do(NewClient(false), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if timing.HTTPCode != http.StatusTeapot {
+		t.Errorf("HTTPCode = %d, want %d", timing.HTTPCode, http.StatusTeapot)
+	}
+	if timing.TCPConnect <= 0 {
+		t.Errorf("TCPConnect = %s, want > 0 for a fresh connection", timing.TCPConnect)
+	}
+	if timing.TimeToFirstByte <= 0 {
+		t.Errorf("TimeToFirstByte = %s, want > 0", timing.TimeToFirstByte)
+	}
+}
+
+func TestTiming_RendersInWriteOutTemplate(t *testing.T) {
+	timing := &Timing{TimeTotal: 250 * 1e6, HTTPCode: 200}
+
+	tmpl, err := template.New("write-out").Parse("{{.TimeTotal}} {{.HTTPCode}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, timing); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := out.String(); !strings.HasSuffix(got, "200") {
+		t.Errorf("rendered write-out = %q, want it to end with the HTTP code", got)
+	}
+}